@@ -0,0 +1,134 @@
+package mflag
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// cmdMeta 描述 AutoRegisterCommands 为一个方法生成子命令时可选的元数据，通过一个零大小
+// 标记字段上的 `cmd`/`short`/`aliases`/`hidden` 标签声明。标记字段不能和方法同名——Go 不允许
+// 字段和方法撞名——所以约定标记字段名为 "<方法名>Cmd"，例如：
+//
+//	ServeCmd struct{} `cmd:"serve" short:"运行 HTTP 服务" aliases:"s,server"`
+//	func (f *Flags) Serve(cmd *cobra.Command, args []string) { ... }
+type cmdMeta struct {
+	Name    string
+	Short   string
+	Aliases []string
+	Hidden  bool
+}
+
+var (
+	cobraCommandType = reflect.TypeOf(&cobra.Command{})
+	stringSliceType  = reflect.TypeOf([]string{})
+	contextType      = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// AutoRegisterCommands 反射遍历 New(flags) 结构体的方法（同时覆盖值接收者和指针接收者），
+// 把签名为 func(cmd *cobra.Command, args []string) 或 func(ctx context.Context) error 的方法
+// 注册为同名（kebab-case）子命令。方法专属的标志分组通过字段上的 `group:"方法名"` 标签声明，
+// bindFieldTag 会以该方法名作为 groupNames 为生成的子命令绑定对应字段。
+func (t *Ts) AutoRegisterCommands() *Ts {
+	if t.flags == nil {
+		return t
+	}
+
+	ptrVal := reflect.ValueOf(t.flags)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.Elem().Kind() != reflect.Struct {
+		return t
+	}
+	structVal := ptrVal.Elem()
+
+	metas := collectCmdMeta(structVal.Type())
+
+	// 与 AddCmd 共用同一个 provider，加载失败或未知字段的错误留到 Execute 里统一返回
+	cfg, _ := t.sharedConfigProvider()
+
+	ptrTyp := ptrVal.Type()
+	for i := 0; i < ptrTyp.NumMethod(); i++ {
+		method := ptrTyp.Method(i)
+		if !method.IsExported() {
+			continue
+		}
+
+		runFunc, ok := adaptMethod(method, ptrVal)
+		if !ok {
+			continue
+		}
+
+		meta, hasMeta := metas[method.Name]
+		name := toKebabCase(method.Name)
+		cmd := &cobra.Command{Use: name, Run: runFunc}
+		if hasMeta {
+			if meta.Name != "" {
+				cmd.Use = meta.Name
+			}
+			cmd.Short = meta.Short
+			cmd.Aliases = meta.Aliases
+			cmd.Hidden = meta.Hidden
+		}
+
+		bindFieldTag(cmd, structVal, "", []string{method.Name}, cfg, "")
+		t.cmdGroups[cmd] = []string{method.Name}
+		t.cc.AddCommand(cmd)
+	}
+
+	return t
+}
+
+// adaptMethod 将反射得到的方法适配为 cobra 可用的 Run 函数，
+// 支持 func(cmd *cobra.Command, args []string) 和 func(ctx context.Context) error 两种签名
+func adaptMethod(method reflect.Method, receiver reflect.Value) (func(cmd *cobra.Command, args []string), bool) {
+	mt := method.Type
+
+	switch {
+	case mt.NumIn() == 3 && mt.NumOut() == 0 &&
+		mt.In(1) == cobraCommandType && mt.In(2) == stringSliceType:
+		return func(cmd *cobra.Command, args []string) {
+			method.Func.Call([]reflect.Value{receiver, reflect.ValueOf(cmd), reflect.ValueOf(args)})
+		}, true
+
+	case mt.NumIn() == 2 && mt.NumOut() == 1 &&
+		mt.In(1) == contextType && mt.Out(0) == errorType:
+		return func(cmd *cobra.Command, args []string) {
+			results := method.Func.Call([]reflect.Value{receiver, reflect.ValueOf(cmd.Context())})
+			if err, _ := results[0].Interface().(error); err != nil {
+				fmt.Printf("执行命令 %s 失败: %v\n", cmd.Name(), err)
+			}
+		}, true
+	}
+
+	return nil, false
+}
+
+// cmdMetaFieldSuffix 是标记字段相对其描述的方法名的固定后缀，见 cmdMeta 上的说明
+const cmdMetaFieldSuffix = "Cmd"
+
+// collectCmdMeta 收集结构体上用于描述子命令元数据的标记字段，以字段名去掉 "Cmd" 后缀
+// 得到的方法名索引
+func collectCmdMeta(structTyp reflect.Type) map[string]cmdMeta {
+	metas := map[string]cmdMeta{}
+	for i := 0; i < structTyp.NumField(); i++ {
+		field := structTyp.Field(i)
+		cmdTag, ok := field.Tag.Lookup("cmd")
+		if !ok {
+			continue
+		}
+
+		methodName := strings.TrimSuffix(field.Name, cmdMetaFieldSuffix)
+		meta := cmdMeta{Name: cmdTag, Short: field.Tag.Get("short")}
+		if aliases := field.Tag.Get("aliases"); aliases != "" {
+			meta.Aliases = strings.Split(aliases, ",")
+		}
+		if field.Tag.Get("hidden") == "true" {
+			meta.Hidden = true
+		}
+		metas[methodName] = meta
+	}
+	return metas
+}
@@ -0,0 +1,51 @@
+package mflag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// autoCmdFlags 用 "<方法名>Cmd" 标记字段声明 Serve 子命令的元数据，字段名与方法名特意不同，
+// 否则 Go 会直接拒绝编译（field and method with the same name）
+type autoCmdFlags struct {
+	ServeCmd struct{} `cmd:"serve" short:"运行 HTTP 服务" aliases:"s,server"`
+	Host     string   `group:"Serve" default:"0.0.0.0"`
+}
+
+func (f *autoCmdFlags) Serve(cmd *cobra.Command, args []string) {}
+
+func TestCollectCmdMetaUsesSuffixedMarkerField(t *testing.T) {
+	metas := collectCmdMeta(reflect.TypeOf(autoCmdFlags{}))
+
+	meta, ok := metas["Serve"]
+	if !ok {
+		t.Fatalf("collectCmdMeta 应该以去掉 Cmd 后缀的方法名 \"Serve\" 索引元数据，实际 keys: %v", metas)
+	}
+	if meta.Name != "serve" || meta.Short != "运行 HTTP 服务" || len(meta.Aliases) != 2 {
+		t.Fatalf("元数据内容不符: %+v", meta)
+	}
+}
+
+func TestAutoRegisterCommandsAppliesMarkerMeta(t *testing.T) {
+	ts := New(&autoCmdFlags{})
+	ts.AutoRegisterCommands()
+
+	var found *cobra.Command
+	for _, cmd := range ts.Cobra().Commands() {
+		if cmd.Name() == "serve" {
+			found = cmd
+			break
+		}
+	}
+	if found == nil {
+		t.Fatalf("未找到生成的 serve 子命令")
+	}
+	if found.Short != "运行 HTTP 服务" {
+		t.Errorf("Short = %q, 期望来自标记字段的 short 标签", found.Short)
+	}
+	if len(found.Aliases) != 2 || found.Aliases[0] != "s" || found.Aliases[1] != "server" {
+		t.Errorf("Aliases = %v, 期望 [s server]", found.Aliases)
+	}
+}
@@ -0,0 +1,333 @@
+package mflag
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigErrorMode 描述配置文件中出现未知字段时的处理方式
+type ConfigErrorMode string
+
+const (
+	ConfigErrorWarn   ConfigErrorMode = "warn"
+	ConfigErrorError  ConfigErrorMode = "error"
+	ConfigErrorIgnore ConfigErrorMode = "ignore"
+)
+
+// configFile 描述一个待加载的配置文件
+type configFile struct {
+	path   string
+	format string
+}
+
+func newConfigFile(path, format string) configFile {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	return configFile{path: path, format: strings.ToLower(format)}
+}
+
+// resolveConfigFile 在搜索路径中定位配置文件的真实路径
+func (t *Ts) resolveConfigFile(cf configFile) (string, error) {
+	if filepath.IsAbs(cf.path) {
+		return cf.path, nil
+	}
+	if _, err := os.Stat(cf.path); err == nil {
+		return cf.path, nil
+	}
+	for _, dir := range t.configSearchPaths {
+		candidate := filepath.Join(dir, cf.path)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("未找到配置文件: %s", cf.path)
+}
+
+// loadConfigFiles 按注册顺序加载所有配置文件，后加载的覆盖先加载的同名字段
+func (t *Ts) loadConfigFiles() (map[string]any, error) {
+	if len(t.configFiles) == 0 {
+		return nil, nil
+	}
+
+	merged := map[string]any{}
+	for _, cf := range t.configFiles {
+		full, err := t.resolveConfigFile(cf)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(full)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件 %s 失败: %v", full, err)
+		}
+
+		var parsed map[string]any
+		switch cf.format {
+		case "yaml", "yml":
+			parsed, err = parseYAMLConfig(data)
+		case "json":
+			parsed, err = parseJSONConfig(data)
+		case "ini":
+			parsed, err = parseINIConfig(data)
+		default:
+			return nil, fmt.Errorf("不支持的配置文件格式: %s", cf.format)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析配置文件 %s 失败: %v", full, err)
+		}
+
+		mergeConfigMap(merged, parsed)
+	}
+	return merged, nil
+}
+
+// newConfigProvider 加载 t 上注册的全部配置文件并返回一个可供 bindFieldTag 查询的 provider
+func (t *Ts) newConfigProvider() (*configProvider, error) {
+	data, err := t.loadConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+	return newConfigProvider(data, t.configErrorMode), nil
+}
+
+// sharedConfigProvider 返回 t 上缓存的 configProvider，只在首次调用时真正加载配置文件。
+// AddCmd、AutoRegisterCommands 注册的每个子命令都共用同一个 provider 和同一份 seen 记录，
+// 这样一个键只要被任意一个子命令的字段消费过就不会再被其它子命令误报为未知字段；
+// 加载失败的错误同样只产生一次，缓存下来供 Execute 返回。
+func (t *Ts) sharedConfigProvider() (*configProvider, error) {
+	if !t.cfgLoaded {
+		t.cfgLoaded = true
+		t.cfgProvider, t.cfgLoadErr = t.newConfigProvider()
+	}
+	return t.cfgProvider, t.cfgLoadErr
+}
+
+func parseYAMLConfig(data []byte) (map[string]any, error) {
+	out := map[string]any{}
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parseJSONConfig(data []byte) (map[string]any, error) {
+	out := map[string]any{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// parseINIConfig 将 INI 文件展开为嵌套 map，section 名按 "." 拆分映射到结构体路径，
+// DEFAULT section 的键直接放在顶层
+func parseINIConfig(data []byte) (map[string]any, error) {
+	f, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]any{}
+	for _, section := range f.Sections() {
+		name := section.Name()
+		for _, key := range section.Keys() {
+			if name == ini.DefaultSection {
+				out[key.Name()] = key.Value()
+				continue
+			}
+			setNestedConfigValue(out, strings.Split(name, "."), key.Name(), key.Value())
+		}
+	}
+	return out, nil
+}
+
+// setNestedConfigValue 按路径在嵌套 map 中设置值，用于 INI section 到结构体路径的映射
+func setNestedConfigValue(root map[string]any, path []string, key, value string) {
+	cur := root
+	for _, seg := range path {
+		next, ok := cur[seg].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			cur[seg] = next
+		}
+		cur = next
+	}
+	cur[key] = value
+}
+
+// mergeConfigMap 将 src 深度合并进 dst，src 的值优先
+func mergeConfigMap(dst, src map[string]any) {
+	for k, v := range src {
+		if sub, ok := v.(map[string]any); ok {
+			if existing, ok := dst[k].(map[string]any); ok {
+				mergeConfigMap(existing, sub)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// configProvider 从配置文件加载得到的嵌套 map 中按点号路径查找字段值
+type configProvider struct {
+	data      map[string]any
+	errorMode ConfigErrorMode
+	seen      map[string]bool
+}
+
+func newConfigProvider(data map[string]any, mode ConfigErrorMode) *configProvider {
+	if mode == "" {
+		mode = ConfigErrorWarn
+	}
+	return &configProvider{data: data, errorMode: mode, seen: map[string]bool{}}
+}
+
+// Lookup 按 flagName（如 "database.pool.max-size" 或 "servers.0.host"）在配置数据中查找
+// 字符串化的值，数字下标既能穿透 map 形式的配置（键为字符串化下标），也能穿透 YAML/JSON
+// 原生的列表语法（"servers: [...]" 解码出的 []any），好让结构体切片两种写法都能用
+func (p *configProvider) Lookup(flagName string) (string, bool) {
+	if p == nil || p.data == nil {
+		return "", false
+	}
+
+	parts := strings.Split(flagName, ".")
+	var cur any = p.data
+	for _, seg := range parts {
+		v, ok := lookupConfigSegment(cur, seg)
+		if !ok {
+			return "", false
+		}
+		cur = v
+	}
+
+	p.seen[flagName] = true
+	return stringifyConfigValue(cur), true
+}
+
+// Len 返回 path 在配置数据中对应的列表长度，供 bindStructSliceField 在没有显式 size 标签
+// 或 "<field>-count" 时，从原生列表语法直接推导结构体切片的大小
+func (p *configProvider) Len(path string) (int, bool) {
+	if p == nil || p.data == nil {
+		return 0, false
+	}
+
+	parts := strings.Split(path, ".")
+	var cur any = p.data
+	for _, seg := range parts {
+		v, ok := lookupConfigSegment(cur, seg)
+		if !ok {
+			return 0, false
+		}
+		cur = v
+	}
+
+	list, ok := cur.([]any)
+	if !ok {
+		return 0, false
+	}
+	return len(list), true
+}
+
+// lookupConfigSegment 在一层配置节点上按 seg 取下一级的值：节点是 map 时按键取值，
+// 节点是 []any 时把 seg 当作下标取值
+func lookupConfigSegment(cur any, seg string) (any, bool) {
+	switch node := cur.(type) {
+	case map[string]any:
+		v, ok := node[seg]
+		return v, ok
+	case []any:
+		idx, err := strconv.Atoi(seg)
+		if err != nil || idx < 0 || idx >= len(node) {
+			return nil, false
+		}
+		return node[idx], true
+	default:
+		return nil, false
+	}
+}
+
+// reportUnknownKeys 根据 errorMode 报告配置文件中未被任何字段消费的键
+func (p *configProvider) reportUnknownKeys() error {
+	if p == nil || p.data == nil || p.errorMode == ConfigErrorIgnore {
+		return nil
+	}
+
+	unknown := collectUnknownConfigKeys(p.data, "", p.seen)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	if p.errorMode == ConfigErrorError {
+		return fmt.Errorf("配置文件中存在未知字段: %s", strings.Join(unknown, ", "))
+	}
+	fmt.Printf("警告: 配置文件中存在未知字段: %s\n", strings.Join(unknown, ", "))
+	return nil
+}
+
+func collectUnknownConfigKeys(data map[string]any, prefix string, seen map[string]bool) []string {
+	var unknown []string
+	for k, v := range data {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		unknown = append(unknown, collectUnknownConfigNode(v, path, seen)...)
+	}
+	return unknown
+}
+
+// collectUnknownConfigNode 递归检查 map 的值或切片的元素是否被消费，切片按下标展开成
+// 与 Lookup 查找时相同的路径（如 "servers.0.host"），使原生列表语法里的未知字段也能被发现
+func collectUnknownConfigNode(v any, path string, seen map[string]bool) []string {
+	switch node := v.(type) {
+	case map[string]any:
+		return collectUnknownConfigKeys(node, path, seen)
+	case []any:
+		// 整个列表已被某个切片字段按路径整体消费（如 "hosts: [a, b]"）时不再逐元素展开
+		if seen[path] {
+			return nil
+		}
+		var unknown []string
+		for i, elem := range node {
+			unknown = append(unknown, collectUnknownConfigNode(elem, fmt.Sprintf("%s.%d", path, i), seen)...)
+		}
+		return unknown
+	default:
+		if !seen[path] {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+// stringifyConfigValue 将解码得到的任意 JSON/YAML/INI 值转换为可交给 pflag.Set 的字符串
+func stringifyConfigValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		// JSON 的数字和 YAML 的小数解码为 float64（YAML 的整数解码为 int，见上），整数值去掉小数部分
+		if val == float64(int64(val)) {
+			return strconv.FormatInt(int64(val), 10)
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = stringifyConfigValue(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,145 @@
+package mflag
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestConfigProviderLookupAndLenHandleNativeListSyntax(t *testing.T) {
+	data, err := parseYAMLConfig([]byte(`
+servers:
+  - host: a.example.com
+    port: 1
+  - host: b.example.com
+    port: 2
+`))
+	if err != nil {
+		t.Fatalf("parseYAMLConfig: %v", err)
+	}
+
+	p := newConfigProvider(data, ConfigErrorError)
+
+	if n, ok := p.Len("servers"); !ok || n != 2 {
+		t.Fatalf("Len(\"servers\") = %d, %v, 期望 2, true", n, ok)
+	}
+	if v, ok := p.Lookup("servers.0.host"); !ok || v != "a.example.com" {
+		t.Fatalf("Lookup(\"servers.0.host\") = %q, %v, 期望 a.example.com, true", v, ok)
+	}
+	if v, ok := p.Lookup("servers.1.port"); !ok || v != "2" {
+		t.Fatalf("Lookup(\"servers.1.port\") = %q, %v, 期望 2, true", v, ok)
+	}
+	if _, ok := p.Lookup("servers.2.host"); ok {
+		t.Fatalf("Lookup(\"servers.2.host\") 应该越界不命中")
+	}
+}
+
+func TestCollectUnknownConfigKeysWalksNativeLists(t *testing.T) {
+	data, err := parseYAMLConfig([]byte(`
+servers:
+  - host: a.example.com
+    typo: oops
+`))
+	if err != nil {
+		t.Fatalf("parseYAMLConfig: %v", err)
+	}
+
+	p := newConfigProvider(data, ConfigErrorError)
+	if _, ok := p.Lookup("servers.0.host"); !ok {
+		t.Fatalf("Lookup(\"servers.0.host\") 应该命中")
+	}
+
+	err = p.reportUnknownKeys()
+	if err == nil {
+		t.Fatalf("servers.0.typo 未被消费，reportUnknownKeys 应该报错")
+	}
+	if got := err.Error(); !strings.Contains(got, "servers.0.typo") {
+		t.Fatalf("错误信息 %q 应该指出 servers.0.typo", got)
+	}
+}
+
+type precedenceFlags struct {
+	Level string `default:"from-default"`
+}
+
+// TestConfigPrecedenceOrder 验证取值优先级：结构体零值 -> default 标签 -> 配置文件 ->
+// ACF_* 环境变量 -> 命令行参数，每一层都应该覆盖前一层
+func TestConfigPrecedenceOrder(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("level: from-config\n"), 0o644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+
+	run := func(setEnv bool, extraArgs []string) (value string, changed bool) {
+		flags := &precedenceFlags{}
+		ts := New(flags)
+		ts.AddConfigFile(cfgPath, "")
+		if setEnv {
+			t.Setenv("ACF_LEVEL", "from-env")
+		}
+
+		ts.AddCmd(func(cmd *cobra.Command, args []string) {
+			value = flags.Level
+			changed = cmd.Flags().Changed("level")
+		}, "run", "", "default")
+		ts.Cobra().SetArgs(append([]string{"run"}, extraArgs...))
+		if err := ts.Execute(); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		return value, changed
+	}
+
+	if got, _ := run(false, nil); got != "from-config" {
+		t.Errorf("配置文件应该覆盖 default 标签: got %q, 期望 from-config", got)
+	}
+	if got, _ := run(true, nil); got != "from-env" {
+		t.Errorf("环境变量应该覆盖配置文件: got %q, 期望 from-env", got)
+	}
+	if got, _ := run(true, []string{"--level", "from-flag"}); got != "from-flag" {
+		t.Errorf("命令行参数应该覆盖环境变量: got %q, 期望 from-flag", got)
+	}
+}
+
+// TestConfigAndEnvDoNotMarkFlagChanged 验证只从配置文件/环境变量取到的值不会让
+// cmd.Flags().Changed() 误报为 true——这个标记应该只反映命令行参数是否真的传入过，
+// 否则 "Run 里用 Changed() 判断用户是否显式传参" 这类写法会被配置文件/环境变量污染
+func TestConfigAndEnvDoNotMarkFlagChanged(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("level: from-config\n"), 0o644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+
+	run := func(setEnv bool, extraArgs []string) bool {
+		flags := &precedenceFlags{}
+		ts := New(flags)
+		ts.AddConfigFile(cfgPath, "")
+		if setEnv {
+			t.Setenv("ACF_LEVEL", "from-env")
+		}
+
+		var changed bool
+		ts.AddCmd(func(cmd *cobra.Command, args []string) {
+			changed = cmd.Flags().Changed("level")
+		}, "run", "", "default")
+		ts.Cobra().SetArgs(append([]string{"run"}, extraArgs...))
+		if err := ts.Execute(); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		return changed
+	}
+
+	if changed := run(false, nil); changed {
+		t.Errorf("只来自配置文件的值不应该让 Changed() 返回 true")
+	}
+	if changed := run(true, nil); changed {
+		t.Errorf("只来自环境变量的值不应该让 Changed() 返回 true")
+	}
+	if changed := run(true, []string{"--level", "from-flag"}); !changed {
+		t.Errorf("命令行显式传入时 Changed() 应该返回 true")
+	}
+}
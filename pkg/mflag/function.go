@@ -23,10 +23,19 @@ type tsFieldTag struct {
 	Required string
 	Note     string
 	Flag     string
+	Size     string
 }
 
 // 绑定命令行标志到结构体字段
-func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNames []string) {
+//
+// cfg 为可选的配置文件 value provider，取值优先级为：
+// 结构体零值 -> default 标签 -> 配置文件 -> ACF_* 环境变量 -> 命令行参数
+//
+// prefix 是已经转换为连字符形式的命令行标志前缀（如 "database-pool"），而 cfgPrefix
+// 是对应的配置文件查找路径，由逐段 kebab-case 的字段名以 "." 拼接而成（如
+// "database.pool"）——两者都会在嵌套时被拍平成连字符，所以必须分开传递，
+// 否则配置文件里的嵌套字段永远查不到。
+func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNames []string, cfg *configProvider, cfgPrefix string) {
 	typ := val.Type()
 
 	for i := 0; i < val.NumField(); i++ {
@@ -45,6 +54,7 @@ func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNam
 			Required: fieldTyp.Tag.Get("required"),
 			Note:     fieldTyp.Tag.Get("note"),
 			Flag:     fieldTyp.Tag.Get("flag"),
+			Size:     fieldTyp.Tag.Get("size"),
 		}
 
 		if tag.Bind == "false" {
@@ -72,6 +82,13 @@ func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNam
 			flagName = toKebabCase(flagName)
 		}
 
+		// 配置文件查找路径始终由字段名逐段 kebab-case 后以 "." 拼接，与 flag 标签无关，
+		// 这样才能和配置文件解析出的嵌套 map 结构对应起来
+		cfgPath := toKebabCase(fieldTyp.Name)
+		if cfgPrefix != "" {
+			cfgPath = cfgPrefix + "." + cfgPath
+		}
+
 		usage := tag.Note
 		defaultValue, err := parseDefaultValue(fieldVal, tag.Default)
 		if err != nil {
@@ -80,8 +97,15 @@ func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNam
 		}
 
 		// 根据字段类型绑定标志
+		// boundAsContainer 标记本字段是否只是递归进入了嵌套结构体/切片，而没有绑定出
+		// 一个真实的标志——这类字段不应该再走 applyProviders，否则会对着一个根本不存在
+		// 的标志调用 cmd.Flags().Set
+		boundAsContainer := false
 		fieldKind := fieldTyp.Type.Kind()
 		switch {
+		case bindCustomValue(cmd, fieldVal, flagName, usage, defaultValue):
+			// 已经通过注册的类型绑定器或字段自身实现的 FlagValue 完成绑定
+
 		case fieldTyp.Type == reflect.TypeOf(time.Duration(0)):
 			defaultDuration := defaultValue.(time.Duration)
 			fieldVal.Set(reflect.ValueOf(defaultDuration))
@@ -152,6 +176,29 @@ func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNam
 			fieldVal.SetString(defaultStr)
 			cmd.Flags().StringVar(fieldVal.Addr().Interface().(*string), flagName, defaultStr, usage)
 
+		// 指向结构体的指针，为 nil 时先分配零值再递归处理
+		case fieldKind == reflect.Ptr && fieldTyp.Type.Elem().Kind() == reflect.Struct:
+			boundAsContainer = true
+			if fieldVal.IsNil() {
+				fieldVal.Set(reflect.New(fieldTyp.Type.Elem()))
+			}
+			bindFieldTag(cmd, fieldVal.Elem(), flagName, groupNames, cfg, cfgPath)
+
+		// 结构体切片，按 size 标签、配置文件或环境变量确定元素个数后逐个递归处理；
+		// 元素类型已注册（如 time.Time、*url.URL）或实现了 FlagValue 的交给下一个 case
+		case fieldKind == reflect.Slice && structSliceElemKind(fieldTyp.Type.Elem()) == reflect.Struct &&
+			!hasRegisteredElemBinder(fieldTyp.Type.Elem()):
+			boundAsContainer = true
+			bindStructSliceField(cmd, fieldVal, flagName, cfgPath, tag, groupNames, cfg)
+
+		// 元素类型是通过 RegisterType 注册的类型（或自身实现了 FlagValue），逐元素复用
+		// 该类型的 binder 解析/格式化，绑定为一个逗号分隔的 pflag.Value
+		case fieldKind == reflect.Slice && hasRegisteredElemBinder(fieldTyp.Type.Elem()):
+			if defaultValue != nil {
+				fieldVal.Set(reflect.ValueOf(defaultValue))
+			}
+			cmd.Flags().Var(newRegisteredSliceValue(fieldVal), flagName, usage)
+
 		// 切片处理
 		case fieldKind == reflect.Slice:
 			elemKind := fieldTyp.Type.Elem().Kind()
@@ -199,6 +246,14 @@ func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNam
 			default:
 				log.Println("不支持的切片元素类型", elemKind)
 			}
+		// map 的值类型是通过 RegisterType 注册的类型（或自身实现了 FlagValue），逐个值复用
+		// 该类型的 binder，绑定为 "k1=v1,k2=v2" 形式的 pflag.Value（键仍要求是 string）
+		case fieldKind == reflect.Map && fieldTyp.Type.Key().Kind() == reflect.String && hasRegisteredElemBinder(fieldTyp.Type.Elem()):
+			if defaultValue != nil {
+				fieldVal.Set(reflect.ValueOf(defaultValue))
+			}
+			cmd.Flags().Var(newRegisteredMapValue(fieldVal), flagName, usage)
+
 		case fieldKind == reflect.Map:
 			elemKind := fieldTyp.Type.Elem().Kind()
 			switch {
@@ -217,23 +272,16 @@ func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNam
 			}
 		case fieldKind == reflect.Struct:
 			// 递归处理嵌套的结构体
-			bindFieldTag(cmd, fieldVal, flagName, groupNames)
+			boundAsContainer = true
+			bindFieldTag(cmd, fieldVal, flagName, groupNames, cfg, cfgPath)
 		default:
 			log.Println("utils.go", "不支持的类型", fieldKind)
 		}
 
-		// 检查环境变量，优先使用命令行参数
-		envPrefix := os.Getenv("PREFIX_ACF")
-		if envPrefix == "" {
-			envPrefix = "ACF_"
-		}
-		envVar := envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
-		envValue := os.Getenv(envVar)
-
-		if envValue != "" && !cmd.Flags().Changed(flagName) {
-			if err := cmd.Flags().Set(flagName, envValue); err != nil {
-				fmt.Printf("从环境变量 %s 设置标志 %s 时出错: %v\n", envVar, flagName, err)
-			}
+		// 按优先级依次应用配置文件、环境变量，两者都在 cobra 解析命令行参数之前生效。
+		// 只递归进入了嵌套结构体/切片的字段没有对应的真实标志，跳过
+		if !boundAsContainer {
+			applyProviders(cmd, flagName, cfgPath, cfg)
 		}
 
 		// 如果标志是必选的，标记为必选
@@ -245,6 +293,108 @@ func bindFieldTag(cmd *cobra.Command, val reflect.Value, prefix string, groupNam
 	}
 }
 
+// applyProviders 依次应用配置文件、环境变量两个 value provider，顺序在 cobra 解析命令行
+// 参数之前生效，后应用的 provider 会覆盖先应用的 provider。cfgPath 是配置文件里的查找路径，
+// 可能与实际注册的标志名 flagName 不同（嵌套字段）
+//
+// pflag 的 Set 首次调用就会把 flag.Changed 置为 true，而这里的 Set 发生在 cobra 解析命令行
+// 参数之前——如果不处理，任何只是从配置文件/环境变量取到值、命令行根本没传的字段也会
+// 报告 Changed() == true。所以每次 Set 之后都把 Changed 复位回 false，真正的 Changed 标记
+// 留给 cobra 稍后解析命令行参数时按实际情况设置。
+func applyProviders(cmd *cobra.Command, flagName, cfgPath string, cfg *configProvider) {
+	if configValue, ok := cfg.Lookup(cfgPath); ok {
+		if err := cmd.Flags().Set(flagName, configValue); err != nil {
+			fmt.Printf("从配置文件设置标志 %s 时出错: %v\n", flagName, err)
+		}
+		resetFlagChanged(cmd, flagName)
+	}
+
+	if envValue, envVar, ok := envValueFor(flagName); ok {
+		if err := cmd.Flags().Set(flagName, envValue); err != nil {
+			fmt.Printf("从环境变量 %s 设置标志 %s 时出错: %v\n", envVar, flagName, err)
+		}
+		resetFlagChanged(cmd, flagName)
+	}
+}
+
+// resetFlagChanged 把 flagName 的 Changed 标记复位为 false，供 applyProviders 在
+// 从配置文件/环境变量取值后调用，使该标记只反映命令行参数是否真的传入过这个标志
+func resetFlagChanged(cmd *cobra.Command, flagName string) {
+	if f := cmd.Flags().Lookup(flagName); f != nil {
+		f.Changed = false
+	}
+}
+
+// envValueFor 按 "<PREFIX_ACF 或 ACF_>大写标志名" 的约定查找环境变量，返回变量值、变量名
+// 以及是否命中，供 applyProviders 和结构体切片的大小探测共用
+func envValueFor(flagName string) (value string, envVar string, ok bool) {
+	envPrefix := os.Getenv("PREFIX_ACF")
+	if envPrefix == "" {
+		envPrefix = "ACF_"
+	}
+	envVar = envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+	value = os.Getenv(envVar)
+	return value, envVar, value != ""
+}
+
+// structSliceElemKind 返回切片元素的结构体 Kind，元素为指向结构体的指针时穿透一层
+func structSliceElemKind(elemTyp reflect.Type) reflect.Kind {
+	if elemTyp.Kind() == reflect.Ptr {
+		return elemTyp.Elem().Kind()
+	}
+	return elemTyp.Kind()
+}
+
+// bindStructSliceField 处理结构体切片字段：按 size 标签、配置文件或环境变量确定元素个数，
+// 据此分配切片后逐个递归绑定，下标作为前缀拼接（如标志 "servers-0-host"，配置路径
+// "servers.0.host"）。
+//
+// 这一步发生在 bindFieldTag 构建命令树时，早于 cobra 解析命令行参数，切片大小此时就已经
+// 固定下来，命令行参数再也无法影响它——因此这里不注册 "<field>-count" 标志（那样的标志
+// 只会制造一个看似能用、实际上每次都被默认值覆盖的假象），只有 size 标签、配置文件与
+// 环境变量能够决定元素个数。
+func bindStructSliceField(cmd *cobra.Command, fieldVal reflect.Value, flagName, cfgPath string, tag *tsFieldTag, groupNames []string, cfg *configProvider) {
+	sliceTyp := fieldVal.Type()
+	elemTyp := sliceTyp.Elem()
+
+	count := 0
+	if tag.Size != "" {
+		if n, err := strconv.Atoi(tag.Size); err == nil {
+			count = n
+		}
+	}
+	if configValue, ok := cfg.Lookup(cfgPath + "-count"); ok {
+		if n, err := strconv.Atoi(configValue); err == nil {
+			count = n
+		}
+	} else if n, ok := cfg.Len(cfgPath); ok {
+		// 没有显式的 "<field>-count" 键时，回退到原生列表语法（"servers: [...]"）
+		// 解码出的 []any 长度，这样数组式配置不需要额外声明一个 count 字段
+		count = n
+	}
+	if envValue, _, ok := envValueFor(flagName + "-count"); ok {
+		if n, err := strconv.Atoi(envValue); err == nil {
+			count = n
+		}
+	}
+
+	fieldVal.Set(reflect.MakeSlice(sliceTyp, count, count))
+
+	for i := 0; i < count; i++ {
+		elemFlagPrefix := fmt.Sprintf("%s.%d", flagName, i)
+		elemCfgPrefix := fmt.Sprintf("%s.%d", cfgPath, i)
+		elemVal := fieldVal.Index(i)
+		if elemTyp.Kind() == reflect.Ptr {
+			if elemVal.IsNil() {
+				elemVal.Set(reflect.New(elemTyp.Elem()))
+			}
+			bindFieldTag(cmd, elemVal.Elem(), elemFlagPrefix, groupNames, cfg, elemCfgPrefix)
+			continue
+		}
+		bindFieldTag(cmd, elemVal, elemFlagPrefix, groupNames, cfg, elemCfgPrefix)
+	}
+}
+
 func toKebabCase(s string) string {
 	// 替换所有非字母数字字符为 '-'
 	var result []rune
@@ -292,6 +442,24 @@ func parseDefaultValue(field reflect.Value, defaultStr string) (any, error) {
 		return reflect.Zero(fieldType).Interface(), nil
 	}
 
+	// 注册类型（time.Time、net.IP、*url.URL、[]time.Duration 等）和字段自身实现的
+	// FlagValue 都复用各自的 Set 方法解析默认值，使 default 标签与命令行参数共用同一条解析路径
+	if binder, ok := lookupTypeBinder(fieldType); ok {
+		temp := reflect.New(fieldType).Elem()
+		if err := binder(temp).Set(defaultStr); err != nil {
+			return nil, fmt.Errorf("解析类型 %s 的默认值失败: %v", fieldType, err)
+		}
+		return temp.Interface(), nil
+	}
+	if field.CanAddr() {
+		if fv, ok := field.Addr().Interface().(FlagValue); ok {
+			if err := fv.Set(defaultStr); err != nil {
+				return nil, fmt.Errorf("解析自定义类型 %s 的默认值失败: %v", fieldType, err)
+			}
+			return field.Interface(), nil
+		}
+	}
+
 	// 针对 time.Duration 类型处理
 	if fieldType == reflect.TypeOf(time.Duration(0)) {
 		duration, err := time.ParseDuration(defaultStr)
@@ -342,6 +510,8 @@ func parseDefaultValue(field reflect.Value, defaultStr string) (any, error) {
 		return defaultStr, nil
 	case reflect.Slice:
 		return parseSliceDefaultValue(fieldType, defaultStr)
+	case reflect.Map:
+		return parseMapDefaultValue(fieldType, defaultStr)
 	default:
 		return nil, fmt.Errorf("不支持的类型: %v", fieldType.Kind())
 	}
@@ -364,6 +534,28 @@ func parseSliceDefaultValue(fieldType reflect.Type, defaultStr string) (interfac
 	return resultSlice.Interface(), nil
 }
 
+// parseMapDefaultValue 处理 map 类型的默认值解析，格式为 "k1=v1,k2=v2"，值的解析复用
+// parseDefaultValue，因此注册类型（如 net.IP）作为 map 的值类型时也能走同一条解析路径
+func parseMapDefaultValue(fieldType reflect.Type, defaultStr string) (any, error) {
+	keyType := fieldType.Key()
+	elemType := fieldType.Elem()
+	resultMap := reflect.MakeMap(fieldType)
+
+	for _, pair := range strings.Split(defaultStr, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("无效的默认键值对: %s", pair)
+		}
+		key := strings.TrimSpace(kv[0])
+		parsedElem, err := parseDefaultValue(reflect.New(elemType).Elem(), strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("解析 map 元素 '%s' 时出错: %v", kv[1], err)
+		}
+		resultMap.SetMapIndex(reflect.ValueOf(key).Convert(keyType), reflect.ValueOf(parsedElem).Convert(elemType))
+	}
+	return resultMap.Interface(), nil
+}
+
 func isInputFromPipe() bool {
 	fileInfo, err := os.Stdin.Stat()
 	if err != nil {
@@ -0,0 +1,62 @@
+package mflag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type upstreamServer struct {
+	Host string
+	TLS  *struct {
+		CertFile string
+	}
+}
+
+type upstreamFlags struct {
+	Servers []upstreamServer `group:"run"`
+}
+
+// TestBindStructSliceFieldSizesFromNativeListConfig 覆盖 "数组式配置" 这一未经 "<field>-count"
+// 或 size 标签显式声明大小的场景：servers 的个数应该直接从 YAML 原生列表的长度推导，
+// 并且递归绑定出的每个元素（包括嵌套的指针结构体）都应该从对应下标读到值
+func TestBindStructSliceFieldSizesFromNativeListConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+servers:
+  - host: a.example.com
+    tls:
+      cert-file: /etc/a.pem
+  - host: b.example.com
+    tls:
+      cert-file: /etc/b.pem
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+
+	flags := &upstreamFlags{}
+	ts := New(flags)
+	ts.AddConfigFile(cfgPath, "")
+	ts.AddCmd(func(cmd *cobra.Command, args []string) {}, "run", "", "run")
+	ts.Cobra().SetArgs([]string{"run"})
+	if err := ts.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if len(flags.Servers) != 2 {
+		t.Fatalf("Servers 长度 = %d, 期望 2", len(flags.Servers))
+	}
+	if flags.Servers[0].Host != "a.example.com" || flags.Servers[1].Host != "b.example.com" {
+		t.Fatalf("Servers host 未从配置文件正确绑定: %+v", flags.Servers)
+	}
+	if flags.Servers[0].TLS == nil || flags.Servers[0].TLS.CertFile != "/etc/a.pem" {
+		t.Fatalf("嵌套指针结构体 TLS 未正确绑定: %+v", flags.Servers[0].TLS)
+	}
+	if flags.Servers[1].TLS == nil || flags.Servers[1].TLS.CertFile != "/etc/b.pem" {
+		t.Fatalf("嵌套指针结构体 TLS 未正确绑定: %+v", flags.Servers[1].TLS)
+	}
+}
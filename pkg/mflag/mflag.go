@@ -9,6 +9,21 @@ import (
 type Ts struct {
 	cc    *cobra.Command
 	flags any
+
+	configFiles       []configFile
+	configSearchPaths []string
+	configErrorMode   ConfigErrorMode
+
+	// 配置文件只加载一次，在 AddCmd/AutoRegisterCommands 首次用到时惰性求值，
+	// 所有子命令共用同一个 provider（以及其内部的 seen 记录），见 sharedConfigProvider
+	cfgLoaded   bool
+	cfgProvider *configProvider
+	cfgLoadErr  error
+
+	// cmdGroups 记录每个生成的子命令绑定时使用的 groupNames，Execute 的
+	// PersistentPreRunE 据此把 Validate 的校验范围收窄到该子命令实际绑定的字段，
+	// 而不是整个结构体
+	cmdGroups map[*cobra.Command][]string
 }
 
 func New(flags any) *Ts {
@@ -16,6 +31,7 @@ func New(flags any) *Ts {
 		cc: &cobra.Command{
 			CompletionOptions: cobra.CompletionOptions{HiddenDefaultCmd: true},
 		},
+		cmdGroups: map[*cobra.Command][]string{},
 	}
 
 	ref.flags = flags
@@ -41,6 +57,25 @@ func (t *Ts) AddCobra(cc *cobra.Command) {
 	t.cc.AddCommand(cc)
 }
 
+// AddConfigFile 注册一个配置文件，在命令执行前用于填充 New(flags) 传入的结构体。
+// format 为空时根据文件扩展名判断（支持 yaml/yml、ini、json）。
+func (t *Ts) AddConfigFile(path string, format string) *Ts {
+	t.configFiles = append(t.configFiles, newConfigFile(path, format))
+	return t
+}
+
+// AddConfigSearchPaths 追加配置文件搜索目录，AddConfigFile 传入的相对路径会依次在这些目录下查找。
+func (t *Ts) AddConfigSearchPaths(dirs ...string) *Ts {
+	t.configSearchPaths = append(t.configSearchPaths, dirs...)
+	return t
+}
+
+// SetConfigErrorMode 设置配置文件中出现未被任何字段消费的键时的处理方式，默认为 warn。
+func (t *Ts) SetConfigErrorMode(mode ConfigErrorMode) *Ts {
+	t.configErrorMode = mode
+	return t
+}
+
 func (t *Ts) AddCmd(
 	runFunc func(cmd *cobra.Command, args []string),
 	name, short string,
@@ -53,7 +88,12 @@ func (t *Ts) AddCmd(
 	}
 	if len(group) > 0 {
 		if t.flags != nil {
-			bindFieldTag(cmd, reflect.ValueOf(t.flags).Elem(), "", group)
+			// 配置文件加载失败或出现未知字段时，真正的 error 留到 Execute 里统一返回，
+			// 这里用 nil-safe 的 cfg 先完成绑定——nil provider 的 Lookup 总是 miss，
+			// 不影响 default/env 等其它取值来源
+			cfg, _ := t.sharedConfigProvider()
+			bindFieldTag(cmd, reflect.ValueOf(t.flags).Elem(), "", group, cfg, "")
+			t.cmdGroups[cmd] = group
 		}
 	}
 
@@ -62,5 +102,22 @@ func (t *Ts) AddCmd(
 }
 
 func (c *Ts) Execute() error {
+	// 所有子命令共用的配置 provider 此时已经被 AddCmd/AutoRegisterCommands 加载过，
+	// 加载失败或（ConfigErrorError 模式下）存在未知字段都在这里中止执行，而不是像
+	// 之前那样只打印一行警告然后继续跑
+	cfg, err := c.sharedConfigProvider()
+	if err != nil {
+		return err
+	}
+	if err := cfg.reportUnknownKeys(); err != nil {
+		return err
+	}
+
+	// 在任意子命令的 Run 执行前完成一次性校验，校验失败时累积所有错误并中止执行；
+	// 只校验该子命令绑定时实际使用的 groupNames，避免其它子命令分组下的约束
+	// （如 nonzero）误伤当前命令
+	c.cc.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return c.Validate(c.cmdGroups[cmd]...)
+	}
 	return c.cc.Execute()
 }
@@ -0,0 +1,284 @@
+package mflag
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// FlagValue 是可供 mflag 绑定的自定义类型需要实现的接口，方法签名与 pflag.Value 保持一致，
+// 因此任意 FlagValue 实现都可以直接传给 cmd.Flags().Var。
+type FlagValue interface {
+	String() string
+	Set(string) error
+	Type() string
+}
+
+// typeBinder 根据字段的 reflect.Value（可寻址）构造出对应的 pflag.Value
+type typeBinder func(reflect.Value) pflag.Value
+
+var typeRegistry = map[reflect.Type]typeBinder{}
+
+// RegisterType 注册一个类型的绑定方式，之后该类型出现在任意结构体字段、切片元素或 map 值中
+// 都会复用同一个绑定器，而不必在 bindFieldTag 里为每个类型写死一个 case。
+func RegisterType(typ reflect.Type, binder func(reflect.Value) pflag.Value) {
+	typeRegistry[typ] = binder
+}
+
+func lookupTypeBinder(typ reflect.Type) (typeBinder, bool) {
+	binder, ok := typeRegistry[typ]
+	return binder, ok
+}
+
+func init() {
+	RegisterType(reflect.TypeOf(time.Time{}), func(v reflect.Value) pflag.Value {
+		return &timeValue{v: v.Addr().Interface().(*time.Time)}
+	})
+	RegisterType(reflect.TypeOf(net.IP{}), func(v reflect.Value) pflag.Value {
+		return &ipValue{v: v.Addr().Interface().(*net.IP)}
+	})
+	RegisterType(reflect.TypeOf(&url.URL{}), func(v reflect.Value) pflag.Value {
+		return &urlValue{v: v.Addr().Interface().(**url.URL)}
+	})
+	RegisterType(reflect.TypeOf([]time.Duration{}), func(v reflect.Value) pflag.Value {
+		return &durationSliceValue{v: v.Addr().Interface().(*[]time.Duration)}
+	})
+}
+
+var flagValueType = reflect.TypeOf((*FlagValue)(nil)).Elem()
+
+// hasRegisteredElemBinder 判断切片元素/map 值的类型能否复用注册表中的 binder 或自身实现
+// 的 FlagValue 绑定，供 bindFieldTag 决定一个 slice/map 字段要不要走逐元素绑定的路径，
+// 使 RegisterType 注册的类型（如 net.IP）也能出现在切片元素、map 值的位置。
+func hasRegisteredElemBinder(elemTyp reflect.Type) bool {
+	if _, ok := lookupTypeBinder(elemTyp); ok {
+		return true
+	}
+	return reflect.PointerTo(elemTyp).Implements(flagValueType)
+}
+
+// resolveElemBinder 返回 hasRegisteredElemBinder 判断为真的元素类型对应的 binder，
+// 优先使用注册表，其次回退到元素自身的 FlagValue 实现
+func resolveElemBinder(elemTyp reflect.Type) typeBinder {
+	if binder, ok := lookupTypeBinder(elemTyp); ok {
+		return binder
+	}
+	return func(v reflect.Value) pflag.Value {
+		return v.Addr().Interface().(FlagValue)
+	}
+}
+
+// registeredSliceValue 把切片字段绑定为一个逗号分隔的 pflag.Value，复用元素类型已注册的
+// binder 解析、格式化每一项，使 RegisterType 注册的类型也能绑定 "[]T" 字段
+type registeredSliceValue struct {
+	val     reflect.Value // 可寻址的切片字段
+	elemTyp reflect.Type
+	binder  typeBinder
+}
+
+func newRegisteredSliceValue(val reflect.Value) *registeredSliceValue {
+	elemTyp := val.Type().Elem()
+	return &registeredSliceValue{val: val, elemTyp: elemTyp, binder: resolveElemBinder(elemTyp)}
+}
+
+func (s *registeredSliceValue) String() string {
+	if s.val.Len() == 0 {
+		return ""
+	}
+	items := make([]string, s.val.Len())
+	for i := 0; i < s.val.Len(); i++ {
+		items[i] = s.binder(s.val.Index(i)).String()
+	}
+	return strings.Join(items, ",")
+}
+
+func (s *registeredSliceValue) Set(v string) error {
+	parts := strings.Split(v, ",")
+	result := reflect.MakeSlice(s.val.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := s.binder(result.Index(i)).Set(strings.TrimSpace(part)); err != nil {
+			return err
+		}
+	}
+	s.val.Set(result)
+	return nil
+}
+
+func (s *registeredSliceValue) Type() string {
+	return "[]" + s.binder(reflect.New(s.elemTyp).Elem()).Type()
+}
+
+// registeredMapValue 把 map[string]T 字段绑定为 "k1=v1,k2=v2" 形式的 pflag.Value，T 为
+// 注册表中已注册的类型或实现了 FlagValue 的类型
+type registeredMapValue struct {
+	val     reflect.Value
+	elemTyp reflect.Type
+	binder  typeBinder
+}
+
+func newRegisteredMapValue(val reflect.Value) *registeredMapValue {
+	elemTyp := val.Type().Elem()
+	return &registeredMapValue{val: val, elemTyp: elemTyp, binder: resolveElemBinder(elemTyp)}
+}
+
+func (m *registeredMapValue) String() string {
+	if m.val.Len() == 0 {
+		return ""
+	}
+	parts := make([]string, 0, m.val.Len())
+	iter := m.val.MapRange()
+	for iter.Next() {
+		// map 里取出的值不可寻址，而 binder 需要 Addr() 才能拿到 *T，所以先拷贝到一个
+		// 可寻址的临时值上
+		elem := reflect.New(m.elemTyp).Elem()
+		elem.Set(iter.Value())
+		parts = append(parts, fmt.Sprintf("%s=%s", iter.Key().String(), m.binder(elem).String()))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (m *registeredMapValue) Set(v string) error {
+	result := reflect.MakeMap(m.val.Type())
+	for _, pair := range strings.Split(v, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("无效的键值对: %s", pair)
+		}
+		elem := reflect.New(m.elemTyp).Elem()
+		if err := m.binder(elem).Set(strings.TrimSpace(kv[1])); err != nil {
+			return err
+		}
+		result.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])), elem)
+	}
+	m.val.Set(result)
+	return nil
+}
+
+func (m *registeredMapValue) Type() string {
+	return "map[string]" + m.binder(reflect.New(m.elemTyp).Elem()).Type()
+}
+
+// bindCustomValue 尝试用注册表或字段自身实现的 FlagValue 绑定标志，绑定成功返回 true。
+func bindCustomValue(cmd *cobra.Command, fieldVal reflect.Value, flagName, usage string, defaultValue any) bool {
+	if binder, ok := lookupTypeBinder(fieldVal.Type()); ok {
+		if defaultValue != nil {
+			fieldVal.Set(reflect.ValueOf(defaultValue))
+		}
+		cmd.Flags().Var(binder(fieldVal), flagName, usage)
+		return true
+	}
+
+	if fieldVal.CanAddr() {
+		if fv, ok := fieldVal.Addr().Interface().(FlagValue); ok {
+			if defaultValue != nil {
+				fieldVal.Set(reflect.ValueOf(defaultValue))
+			}
+			cmd.Flags().Var(fv, flagName, usage)
+			return true
+		}
+	}
+
+	return false
+}
+
+// timeValue 以 RFC3339 格式绑定 time.Time
+type timeValue struct{ v *time.Time }
+
+func (t *timeValue) String() string {
+	if t.v == nil || t.v.IsZero() {
+		return ""
+	}
+	return t.v.Format(time.RFC3339)
+}
+
+func (t *timeValue) Set(s string) error {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+	*t.v = parsed
+	return nil
+}
+
+func (t *timeValue) Type() string { return "time" }
+
+// ipValue 绑定 net.IP
+type ipValue struct{ v *net.IP }
+
+func (i *ipValue) String() string {
+	if i.v == nil || *i.v == nil {
+		return ""
+	}
+	return i.v.String()
+}
+
+func (i *ipValue) Set(s string) error {
+	parsed := net.ParseIP(s)
+	if parsed == nil {
+		return fmt.Errorf("无效的 IP 地址: %s", s)
+	}
+	*i.v = parsed
+	return nil
+}
+
+func (i *ipValue) Type() string { return "ip" }
+
+// urlValue 绑定 *url.URL
+type urlValue struct{ v **url.URL }
+
+func (u *urlValue) String() string {
+	if u.v == nil || *u.v == nil {
+		return ""
+	}
+	return (*u.v).String()
+}
+
+func (u *urlValue) Set(s string) error {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	*u.v = parsed
+	return nil
+}
+
+func (u *urlValue) Type() string { return "url" }
+
+// durationSliceValue 绑定 []time.Duration，逗号分隔
+type durationSliceValue struct{ v *[]time.Duration }
+
+func (d *durationSliceValue) String() string {
+	if d.v == nil {
+		return ""
+	}
+	items := make([]string, len(*d.v))
+	for i, dur := range *d.v {
+		items[i] = dur.String()
+	}
+	return strings.Join(items, ",")
+}
+
+func (d *durationSliceValue) Set(s string) error {
+	var result []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(part)
+		if err != nil {
+			return err
+		}
+		result = append(result, parsed)
+	}
+	*d.v = result
+	return nil
+}
+
+func (d *durationSliceValue) Type() string { return "durationSlice" }
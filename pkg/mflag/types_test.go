@@ -0,0 +1,37 @@
+package mflag
+
+import (
+	"net"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type registeredDefaultFlags struct {
+	IPs    []net.IP          `default:"1.1.1.1,2.2.2.2" group:"run"`
+	Routes map[string]net.IP `default:"a=1.1.1.1,b=2.2.2.2" group:"run"`
+	SoloIP net.IP            `default:"3.3.3.3" group:"run"`
+}
+
+// TestRegisteredSliceAndMapApplyDefaultValue 覆盖请求要求的 "default 标签对注册类型
+// round-trip 通过 Set" 场景：标量字段早已正确工作，这里验证切片、map 元素类型同样
+// 走注册表的场景不会把 default 标签丢在地上
+func TestRegisteredSliceAndMapApplyDefaultValue(t *testing.T) {
+	flags := &registeredDefaultFlags{}
+	ts := New(flags)
+	ts.AddCmd(func(cmd *cobra.Command, args []string) {}, "run", "", "run")
+	ts.Cobra().SetArgs([]string{"run"})
+	if err := ts.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if len(flags.IPs) != 2 || flags.IPs[0].String() != "1.1.1.1" || flags.IPs[1].String() != "2.2.2.2" {
+		t.Fatalf("[]net.IP 的 default 标签未生效: %v", flags.IPs)
+	}
+	if len(flags.Routes) != 2 || flags.Routes["a"].String() != "1.1.1.1" || flags.Routes["b"].String() != "2.2.2.2" {
+		t.Fatalf("map[string]net.IP 的 default 标签未生效: %v", flags.Routes)
+	}
+	if flags.SoloIP.String() != "3.3.3.3" {
+		t.Fatalf("标量 net.IP 的 default 标签未生效: %v", flags.SoloIP)
+	}
+}
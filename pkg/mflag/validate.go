@@ -0,0 +1,222 @@
+package mflag
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tsValidateTag 收集字段上声明的校验约束标签
+type tsValidateTag struct {
+	Group   string
+	Min     string
+	Max     string
+	Len     string
+	OneOf   string
+	Regex   string
+	NonZero string
+	Cidr    string
+	URL     string
+}
+
+// validateErrors 把校验过程中收集到的所有错误合并为一个错误返回，
+// 而不是遇到第一个不满足的约束就终止
+type validateErrors []error
+
+func (e validateErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate 对 New(flags) 传入的结构体执行一次校验，依据 min/max/len/oneof/regex/nonzero/cidr/url
+// 等标签声明的约束检查当前值，所有失败会被收集进一条 multi-error 返回。
+//
+// groupNames 限定本次校验的范围：带 group 标签的字段只在其分组出现在 groupNames 中时才会
+// 被检查，未声明 group 的字段始终检查——这与 bindFieldTag 绑定标志时的分组过滤规则完全一致，
+// 使 Execute 只校验当前子命令实际绑定的那部分字段，而不会被其它子命令分组下的约束误伤。
+// 不传 groupNames 等价于只校验未分组的字段。
+func (t *Ts) Validate(groupNames ...string) error {
+	if t.flags == nil {
+		return nil
+	}
+	ptrVal := reflect.ValueOf(t.flags)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []error
+	validateStruct(ptrVal.Elem(), "", groupNames, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return validateErrors(errs)
+}
+
+// validateStruct 递归遍历结构体字段，嵌套结构体/指针/切片以点号路径累加字段名，
+// 使错误信息指向 "database.pool.max-size" 这样的完整路径而不是裸字段名
+func validateStruct(val reflect.Value, prefix string, groupNames []string, errs *[]error) {
+	typ := val.Type()
+
+	for i := 0; i < val.NumField(); i++ {
+		fieldVal := val.Field(i)
+		fieldTyp := typ.Field(i)
+		if !fieldVal.CanSet() {
+			continue
+		}
+
+		tag := &tsValidateTag{
+			Group:   fieldTyp.Tag.Get("group"),
+			Min:     fieldTyp.Tag.Get("min"),
+			Max:     fieldTyp.Tag.Get("max"),
+			Len:     fieldTyp.Tag.Get("len"),
+			OneOf:   fieldTyp.Tag.Get("oneof"),
+			Regex:   fieldTyp.Tag.Get("regex"),
+			NonZero: fieldTyp.Tag.Get("nonzero"),
+			Cidr:    fieldTyp.Tag.Get("cidr"),
+			URL:     fieldTyp.Tag.Get("url"),
+		}
+
+		// 与 bindFieldTag 的分组过滤规则保持一致：带 group 标签的字段只在其分组被
+		// 请求校验时才检查，未声明 group 的字段不受影响
+		if tag.Group != "" && !slices.Contains(groupNames, tag.Group) {
+			continue
+		}
+
+		name := fieldTyp.Tag.Get("flag")
+		if name == "" {
+			name = toKebabCase(fieldTyp.Name)
+		}
+		fieldPath := name
+		if prefix != "" {
+			fieldPath = prefix + "." + name
+		}
+
+		switch fieldTyp.Type.Kind() {
+		case reflect.Slice, reflect.Array:
+			validateLen(fieldVal.Len(), tag.Len, fieldPath, errs)
+			// len 约束只衡量切片本身的长度，不应该再对每个元素重复套用一遍，
+			// 否则 `Hosts []string \`len:"2"\`` 会被误读成要求每个元素都恰好 2 个字符
+			elemTag := *tag
+			elemTag.Len = ""
+			for j := 0; j < fieldVal.Len(); j++ {
+				validateElement(fieldVal.Index(j), &elemTag, fmt.Sprintf("%s.%d", fieldPath, j), groupNames, errs)
+			}
+
+		case reflect.Map:
+			validateLen(fieldVal.Len(), tag.Len, fieldPath, errs)
+
+		case reflect.Ptr:
+			if fieldVal.IsNil() {
+				if tag.NonZero == "true" {
+					*errs = append(*errs, fmt.Errorf("%s: 不能为空", fieldPath))
+				}
+				continue
+			}
+			validateElement(fieldVal.Elem(), tag, fieldPath, groupNames, errs)
+
+		default:
+			validateElement(fieldVal, tag, fieldPath, groupNames, errs)
+		}
+	}
+}
+
+// validateElement 对单个值（结构体字段、切片元素或指针解引用后的值）应用约束
+func validateElement(fieldVal reflect.Value, tag *tsValidateTag, fieldPath string, groupNames []string, errs *[]error) {
+	if fieldVal.Kind() == reflect.Struct && fieldVal.Type() != reflect.TypeOf(time.Time{}) {
+		validateStruct(fieldVal, fieldPath, groupNames, errs)
+		return
+	}
+
+	// 指针元素与 validateStruct 里顶层指针字段的处理保持一致：nil 时只看 nonzero，
+	// 否则解引用递归——这样 bindFieldTag 支持的 []*SomeStruct 切片元素上的约束才不会被漏掉
+	if fieldVal.Kind() == reflect.Ptr {
+		if fieldVal.IsNil() {
+			if tag.NonZero == "true" {
+				*errs = append(*errs, fmt.Errorf("%s: 不能为空", fieldPath))
+			}
+			return
+		}
+		validateElement(fieldVal.Elem(), tag, fieldPath, groupNames, errs)
+		return
+	}
+
+	if tag.NonZero == "true" && fieldVal.IsZero() {
+		*errs = append(*errs, fmt.Errorf("%s: 不能为零值", fieldPath))
+	}
+
+	switch fieldVal.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		validateNumberRange(float64(fieldVal.Int()), tag, fieldPath, errs)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		validateNumberRange(float64(fieldVal.Uint()), tag, fieldPath, errs)
+	case reflect.Float32, reflect.Float64:
+		validateNumberRange(fieldVal.Float(), tag, fieldPath, errs)
+	case reflect.String:
+		validateStringValue(fieldVal.String(), tag, fieldPath, errs)
+	}
+}
+
+func validateNumberRange(v float64, tag *tsValidateTag, fieldPath string, errs *[]error) {
+	if tag.Min != "" {
+		if min, err := strconv.ParseFloat(tag.Min, 64); err == nil && v < min {
+			*errs = append(*errs, fmt.Errorf("%s: 取值 %v 小于最小值 %v", fieldPath, v, min))
+		}
+	}
+	if tag.Max != "" {
+		if max, err := strconv.ParseFloat(tag.Max, 64); err == nil && v > max {
+			*errs = append(*errs, fmt.Errorf("%s: 取值 %v 大于最大值 %v", fieldPath, v, max))
+		}
+	}
+}
+
+func validateStringValue(v string, tag *tsValidateTag, fieldPath string, errs *[]error) {
+	if tag.Len != "" {
+		validateLen(len(v), tag.Len, fieldPath, errs)
+	}
+	if tag.OneOf != "" {
+		options := strings.Split(tag.OneOf, ",")
+		if !slices.Contains(options, v) {
+			*errs = append(*errs, fmt.Errorf("%s: 取值 %q 不在允许的范围 [%s] 内", fieldPath, v, tag.OneOf))
+		}
+	}
+	if tag.Regex != "" {
+		re, err := regexp.Compile(tag.Regex)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: 正则表达式无效: %v", fieldPath, err))
+		} else if !re.MatchString(v) {
+			*errs = append(*errs, fmt.Errorf("%s: 取值 %q 不匹配正则 %s", fieldPath, v, tag.Regex))
+		}
+	}
+	if tag.Cidr == "true" {
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: 不是合法的 CIDR: %v", fieldPath, err))
+		}
+	}
+	if tag.URL == "true" {
+		if _, err := url.ParseRequestURI(v); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: 不是合法的 URL: %v", fieldPath, err))
+		}
+	}
+}
+
+func validateLen(actual int, lenTag string, fieldPath string, errs *[]error) {
+	if lenTag == "" {
+		return
+	}
+	expected, err := strconv.Atoi(lenTag)
+	if err != nil {
+		return
+	}
+	if actual != expected {
+		*errs = append(*errs, fmt.Errorf("%s: 长度 %d 不等于要求的 %d", fieldPath, actual, expected))
+	}
+}
@@ -0,0 +1,113 @@
+package mflag
+
+import "testing"
+
+type poolConfig struct {
+	MaxSize int `min:"1" max:"10"`
+}
+
+type databaseConfig struct {
+	Pool  poolConfig
+	Hosts []string `len:"2" nonzero:"true"`
+}
+
+type validateFlags struct {
+	Database databaseConfig
+	Env      string `oneof:"dev,staging,prod"`
+}
+
+// TestValidateWalkerNestedPathsAndPerElementConstraints 覆盖校验 walker 的核心行为：
+// 嵌套结构体的字段路径要点号拼接（database.pool.max-size），切片本身的 len 约束不应该
+// 再逐元素套用一遍，而 nonzero 这类逐元素约束要按下标展开
+func TestValidateWalkerNestedPathsAndPerElementConstraints(t *testing.T) {
+	flags := &validateFlags{
+		Database: databaseConfig{
+			Pool:  poolConfig{MaxSize: 20},
+			Hosts: []string{"a", ""},
+		},
+		Env: "qa",
+	}
+	ts := New(flags)
+
+	err := ts.Validate()
+	if err == nil {
+		t.Fatalf("预期校验失败")
+	}
+	msg := err.Error()
+
+	for _, want := range []string{
+		"database.pool.max-size",
+		"database.hosts.1",
+		"env",
+	} {
+		if !containsSubstring(msg, want) {
+			t.Errorf("错误信息 %q 应该包含 %q", msg, want)
+		}
+	}
+}
+
+func TestValidateWalkerPassesWhenConstraintsSatisfied(t *testing.T) {
+	flags := &validateFlags{
+		Database: databaseConfig{
+			Pool:  poolConfig{MaxSize: 5},
+			Hosts: []string{"a", "b"},
+		},
+		Env: "prod",
+	}
+	ts := New(flags)
+
+	if err := ts.Validate(); err != nil {
+		t.Fatalf("预期校验通过，实际: %v", err)
+	}
+}
+
+type upstreamTarget struct {
+	Name string `nonzero:"true"`
+}
+
+type ptrSliceFlags struct {
+	Targets []*upstreamTarget
+}
+
+// TestValidateWalkerChecksPointerSliceElements 覆盖 bindFieldTag 支持的 []*SomeStruct 场景：
+// 切片元素是指针时，validateElement 要解引用递归而不是把约束悄悄跳过
+func TestValidateWalkerChecksPointerSliceElements(t *testing.T) {
+	flags := &ptrSliceFlags{
+		Targets: []*upstreamTarget{
+			{Name: "a"},
+			{Name: ""},
+		},
+	}
+	ts := New(flags)
+
+	err := ts.Validate()
+	if err == nil {
+		t.Fatalf("预期校验失败：targets.1.name 为空")
+	}
+	if !containsSubstring(err.Error(), "targets.1.name") {
+		t.Errorf("错误信息 %q 应该包含 targets.1.name", err.Error())
+	}
+}
+
+func TestValidateWalkerPassesPointerSliceElementsWhenSatisfied(t *testing.T) {
+	flags := &ptrSliceFlags{
+		Targets: []*upstreamTarget{
+			{Name: "a"},
+			{Name: "b"},
+		},
+	}
+	ts := New(flags)
+
+	if err := ts.Validate(); err != nil {
+		t.Fatalf("预期校验通过，实际: %v", err)
+	}
+}
+
+func containsSubstring(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}